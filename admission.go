@@ -0,0 +1,188 @@
+package main
+
+import "hash/fnv"
+
+// cmsDepth is the number of independent hash rows in the count-min sketch.
+// Four rows is the standard TinyLFU trade-off between estimation accuracy
+// and the cost of reading/updating the sketch on every access.
+const cmsDepth = 4
+
+// maxCounter is the saturation point of each 4-bit counter.
+const maxCounter = 15
+
+// countMinSketch estimates how often a key has been seen recently, using
+// cmsDepth rows of 4-bit saturating counters. Counters are periodically
+// halved ("aged") so the estimate tracks recent activity rather than
+// accumulating forever.
+type countMinSketch struct {
+	rows    [cmsDepth][]uint8
+	width   uint64 // power of two; counters per row
+	inserts uint64
+	resetAt uint64 // halve every row once this many inserts have landed
+}
+
+func newCountMinSketch(width uint32) *countMinSketch {
+	if width < 16 {
+		width = 16
+	}
+	width = nextPowerOf2(width)
+
+	s := &countMinSketch{
+		width:   uint64(width),
+		resetAt: uint64(width) * cmsDepth,
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+// rowHashes derives cmsDepth independent hashes for key by salting FNV-1a
+// with the row index, rather than requiring cmsDepth distinct hash
+// functions.
+func rowHashes(key string) [cmsDepth]uint64 {
+	var hs [cmsDepth]uint64
+	for row := range hs {
+		h := fnv.New64a()
+		h.Write([]byte{byte(row)})
+		h.Write([]byte(key))
+		hs[row] = h.Sum64()
+	}
+	return hs
+}
+
+// add increments key's counters, aging the sketch if it just crossed the
+// reset threshold. It reports whether aging happened.
+func (s *countMinSketch) add(key string) (aged bool) {
+	mask := s.width - 1
+	for row, h := range rowHashes(key) {
+		idx := h & mask
+		if s.rows[row][idx] < maxCounter {
+			s.rows[row][idx]++
+		}
+	}
+	s.inserts++
+	if s.inserts >= s.resetAt {
+		s.age()
+		return true
+	}
+	return false
+}
+
+// age halves every counter, giving recent activity more weight than old.
+func (s *countMinSketch) age() {
+	for _, row := range s.rows {
+		for i := range row {
+			row[i] >>= 1
+		}
+	}
+	s.inserts = 0
+}
+
+// estimate returns key's estimated frequency: the minimum across rows,
+// which bounds the error introduced by hash collisions.
+func (s *countMinSketch) estimate(key string) uint8 {
+	mask := s.width - 1
+	min := uint8(maxCounter)
+	for row, h := range rowHashes(key) {
+		idx := h & mask
+		if v := s.rows[row][idx]; v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+// doorkeeper is a bloom filter guarding the sketch from one-hit-wonders: a
+// key's first sighting only sets a bit here, so a single Put/Get for a key
+// that's never seen again doesn't consume a sketch slot.
+type doorkeeper struct {
+	bits []uint64
+	mask uint64
+}
+
+func newDoorkeeper(width uint32) *doorkeeper {
+	return &doorkeeper{
+		bits: make([]uint64, (int(width)+63)/64),
+		mask: uint64(width - 1),
+	}
+}
+
+func (d *doorkeeper) has(h uint64) bool {
+	idx := h & d.mask
+	return d.bits[idx/64]&(1<<(idx%64)) != 0
+}
+
+func (d *doorkeeper) set(h uint64) {
+	idx := h & d.mask
+	d.bits[idx/64] |= 1 << (idx % 64)
+}
+
+func (d *doorkeeper) reset() {
+	for i := range d.bits {
+		d.bits[i] = 0
+	}
+}
+
+// tinyLFUFilter pairs a count-min sketch with a doorkeeper to estimate key
+// popularity, as used by NewLRUCacheTinyLFU to decide whether a newly
+// inserted key deserves to evict the current LRU victim.
+type tinyLFUFilter struct {
+	sketch     *countMinSketch
+	doorkeeper *doorkeeper
+}
+
+// newTinyLFUFilter sizes the sketch and doorkeeper to roughly 10x the
+// shard's item capacity, the ratio TinyLFU's authors found sufficient to
+// keep collision-driven estimation error low.
+func newTinyLFUFilter(capacity int) *tinyLFUFilter {
+	width := nextPowerOf2(uint32(capacity * 10))
+	return &tinyLFUFilter{
+		sketch:     newCountMinSketch(width),
+		doorkeeper: newDoorkeeper(width),
+	}
+}
+
+// record registers one sighting of key. The first sighting only flips a
+// doorkeeper bit; only the second and later sightings bump the sketch.
+func (f *tinyLFUFilter) record(key string) {
+	h := rowHashes(key)[0]
+	if !f.doorkeeper.has(h) {
+		f.doorkeeper.set(h)
+		return
+	}
+	if f.sketch.add(key) {
+		f.doorkeeper.reset()
+	}
+}
+
+// frequency returns key's estimated recent access count.
+func (f *tinyLFUFilter) frequency(key string) uint8 {
+	return f.sketch.estimate(key)
+}
+
+// NewLRUCacheTinyLFU creates an item-count-bounded cache that gates
+// admission with a TinyLFU frequency filter: once a shard is full, a new
+// key only evicts the current LRU victim if it has been seen at least as
+// often, which keeps a burst of one-hit-wonders from pushing out entries
+// the working set actually depends on.
+func NewLRUCacheTinyLFU(capacity int) *LRUCache {
+	numShards := numShardsFor()
+	shardCapacity := capacity / int(numShards)
+	if shardCapacity < 1 {
+		shardCapacity = 1
+	}
+
+	cache := &LRUCache{
+		shards:    make([]*Shard, numShards),
+		shardMask: numShards - 1,
+		stopCh:    make(chan struct{}),
+	}
+	for i := uint32(0); i < numShards; i++ {
+		shard := newShard(shardCapacity, LRU())
+		shard.admit = newTinyLFUFilter(shardCapacity)
+		cache.shards[i] = shard
+	}
+	go cache.runJanitor()
+	return cache
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// zipfKeys generates n keys drawn from a Zipfian distribution over a
+// universe larger than any cache capacity under test, so a meaningful
+// fraction of requests target unpopular, ideally-rejected keys.
+func zipfKeys(n int) []string {
+	r := rand.New(rand.NewSource(1))
+	zipf := rand.NewZipf(r, 1.2, 1, 99999)
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key:%d", zipf.Uint64())
+	}
+	return keys
+}
+
+// benchmarkHitRatio replays the same Zipfian workload through newCache and
+// reports the resulting hit ratio as a custom benchmark metric, letting
+// `go test -bench` compare eviction policies head-to-head.
+func benchmarkHitRatio(b *testing.B, newCache func(capacity int) *LRUCache) {
+	const capacity = 10000
+	keys := zipfKeys(b.N)
+	cache := newCache(capacity)
+	defer cache.Close()
+
+	var hits int
+	b.ResetTimer()
+	for _, k := range keys {
+		if _, ok := cache.Get(k); ok {
+			hits++
+		} else {
+			cache.Put(k, k)
+		}
+	}
+	b.StopTimer()
+
+	if len(keys) > 0 {
+		b.ReportMetric(float64(hits)/float64(len(keys)), "hit-ratio")
+	}
+}
+
+func BenchmarkHitRatioLRU(b *testing.B) {
+	benchmarkHitRatio(b, NewLRUCache)
+}
+
+func BenchmarkHitRatioTinyLFU(b *testing.B) {
+	benchmarkHitRatio(b, NewLRUCacheTinyLFU)
+}
@@ -0,0 +1,76 @@
+package main
+
+import "testing"
+
+func TestBytesCacheEvictsOnOverflow(t *testing.T) {
+	// One shard of a realistic numShardsFor() count won't see enough keys to
+	// reliably overflow in a small test, so exercise a single shard directly.
+	shard := newByteShard(10, nil, LRU())
+	shard.sizer = func(key string, v interface{}) int64 { return int64(len(key)) }
+
+	for _, k := range []string{"aaa", "bbb", "ccc", "ddd"} {
+		shard.mu.Lock()
+		for shard.wouldOverflow(int64(len(k))) {
+			shard.evictLRU()
+		}
+		shard.addToFront(&Node{key: k, value: k, cost: int64(len(k))})
+		shard.cache[k] = shard.head.next
+		shard.size++
+		shard.bytesUsed += int64(len(k))
+		shard.mu.Unlock()
+	}
+
+	if shard.bytesUsed > shard.maxBytes {
+		t.Fatalf("bytesUsed %d exceeds maxBytes %d", shard.bytesUsed, shard.maxBytes)
+	}
+	if _, ok := shard.cache["aaa"]; ok {
+		t.Fatal("expected the oldest entry to have been evicted to make room")
+	}
+	if _, ok := shard.cache["ddd"]; !ok {
+		t.Fatal("expected the newest entry to be present")
+	}
+}
+
+func TestNewLRUCacheBytesRespectsBudget(t *testing.T) {
+	c := NewLRUCacheBytes(1<<20, nil)
+	defer c.Close()
+
+	big := make([]byte, 1<<16)
+	for i := 0; i < 64; i++ {
+		c.Put(string(rune('a'+i%26))+string(rune(i)), big)
+	}
+
+	if got := c.BytesUsed(); got > 1<<20 {
+		t.Fatalf("expected total bytesUsed to stay within budget, got %d", got)
+	}
+}
+
+func TestPutWithTTLEvictsOnUpdateOverflow(t *testing.T) {
+	// Growing an existing key's value must re-check the budget just like
+	// inserting a new key does, not just silently blow past maxBytes.
+	shard := newByteShard(10, func(key string, v interface{}) int64 { return int64(len(key)) }, LRU())
+	c := &LRUCache{shards: []*Shard{shard}, shardMask: 0, stopCh: make(chan struct{})}
+	defer c.Close()
+
+	c.Put("k", "v")
+	c.Put("other", "v")
+
+	shard.sizer = func(key string, v interface{}) int64 { return 9 } // "k" (9) plus "other" (5) now exceeds maxBytes
+	c.Put("k", "bigger")
+
+	if shard.bytesUsed > shard.maxBytes {
+		t.Fatalf("bytesUsed %d exceeds maxBytes %d after growing an existing key", shard.bytesUsed, shard.maxBytes)
+	}
+	if _, ok := shard.cache["other"]; ok {
+		t.Fatal("expected the unrelated entry to have been evicted to make room for the grown key")
+	}
+}
+
+func TestDefaultByteSizer(t *testing.T) {
+	if got := defaultByteSizer("ab", "cde"); got != 5 {
+		t.Fatalf("expected len(key)+len(value) = 5, got %d", got)
+	}
+	if got := defaultByteSizer("ab", []byte{1, 2, 3, 4}); got != 6 {
+		t.Fatalf("expected len(key)+len(value) = 6, got %d", got)
+	}
+}
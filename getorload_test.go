@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrLoadDedupesConcurrentMisses(t *testing.T) {
+	c := NewLRUCache(16)
+	defer c.Close()
+
+	var calls int32
+	release := make(chan struct{})
+	loader := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "v", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err := c.GetOrLoad("k", loader)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			results[i] = v
+		}(i)
+	}
+
+	time.Sleep(20 * time.Millisecond) // let every goroutine reach the inflight wait
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("expected loader to run once, ran %d times", got)
+	}
+	for i, v := range results {
+		if v != "v" {
+			t.Fatalf("result %d: expected %q, got %v", i, "v", v)
+		}
+	}
+}
+
+func TestGetOrLoadCachesResult(t *testing.T) {
+	c := NewLRUCache(16)
+	defer c.Close()
+
+	var calls int32
+	loader := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return "v", nil
+	}
+
+	for i := 0; i < 3; i++ {
+		v, err := c.GetOrLoad("k", loader)
+		if err != nil || v != "v" {
+			t.Fatalf("unexpected result: %v %v", v, err)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("expected loader to run once across repeated calls, ran %d times", got)
+	}
+}
+
+func TestGetOrLoadCtxTimesOutWaitingOnOther(t *testing.T) {
+	c := NewLRUCache(16)
+	defer c.Close()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go c.GetOrLoad("k", func() (interface{}, error) {
+		close(started)
+		<-release
+		return "v", nil
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := c.GetOrLoadCtx(ctx, "k", func() (interface{}, error) {
+		t.Fatal("follower must not run its own loader while one is already inflight")
+		return nil, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	close(release)
+}
+
+func TestGetOrLoadPanicUnwedgesKey(t *testing.T) {
+	c := NewLRUCache(16)
+	defer c.Close()
+
+	func() {
+		defer func() { recover() }()
+		c.GetOrLoad("k", func() (interface{}, error) {
+			panic("boom")
+		})
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	v, err := c.GetOrLoadCtx(ctx, "k", func() (interface{}, error) {
+		return "ok", nil
+	})
+	if err != nil || v != "ok" {
+		t.Fatalf("expected a later caller to load successfully, got %v %v", v, err)
+	}
+}
+
+func TestGetOrLoadFollowerSeesErrorWhenLeaderPanics(t *testing.T) {
+	c := NewLRUCache(16)
+	defer c.Close()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		defer func() { recover() }()
+		c.GetOrLoad("k", func() (interface{}, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+	<-started
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var followerVal interface{}
+	var followerErr error
+	go func() {
+		defer wg.Done()
+		followerVal, followerErr = c.GetOrLoad("k", func() (interface{}, error) {
+			t.Error("follower must not run its own loader while one is already inflight")
+			return nil, nil
+		})
+	}()
+
+	time.Sleep(20 * time.Millisecond) // let the follower reach the inflight wait
+	close(release)
+	wg.Wait()
+
+	if !errors.Is(followerErr, errLoaderPanicked) {
+		t.Fatalf("expected errLoaderPanicked, got val=%v err=%v", followerVal, followerErr)
+	}
+}
+
+func TestGetOrLoadPropagatesLoaderError(t *testing.T) {
+	c := NewLRUCache(16)
+	defer c.Close()
+
+	wantErr := errors.New("load failed")
+	_, err := c.GetOrLoad("k", func() (interface{}, error) {
+		return nil, wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("expected %v, got %v", wantErr, err)
+	}
+	if _, ok := c.Get("k"); ok {
+		t.Fatal("expected a failed load not to populate the cache")
+	}
+}
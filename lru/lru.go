@@ -0,0 +1,270 @@
+// Package lru implements a generic, sharded, thread-safe LRU cache.
+package lru
+
+import (
+	"fmt"
+	"hash/maphash"
+	"runtime"
+	"sync"
+)
+
+type node[K comparable, V any] struct {
+	key   K
+	value V
+	prev  *node[K, V]
+	next  *node[K, V]
+}
+
+type shard[K comparable, V any] struct {
+	mu       sync.RWMutex
+	capacity int
+	size     int
+	items    map[K]*node[K, V]
+	head     *node[K, V]
+	tail     *node[K, V]
+}
+
+func newShard[K comparable, V any](capacity int) *shard[K, V] {
+	s := &shard[K, V]{
+		capacity: capacity,
+		items:    make(map[K]*node[K, V]),
+		head:     &node[K, V]{},
+		tail:     &node[K, V]{},
+	}
+	// Initialize doubly-linked list with sentinel nodes
+	s.head.next = s.tail
+	s.tail.prev = s.head
+	return s
+}
+
+func (s *shard[K, V]) addToFront(n *node[K, V]) {
+	n.prev = s.head
+	n.next = s.head.next
+	s.head.next.prev = n
+	s.head.next = n
+}
+
+func (s *shard[K, V]) removeNode(n *node[K, V]) {
+	n.prev.next = n.next
+	n.next.prev = n.prev
+}
+
+func (s *shard[K, V]) moveToFront(n *node[K, V]) {
+	s.removeNode(n)
+	s.addToFront(n)
+}
+
+func (s *shard[K, V]) evictOldest() {
+	oldest := s.tail.prev
+	if oldest != s.head {
+		s.removeNode(oldest)
+		delete(s.items, oldest.key)
+		s.size--
+	}
+}
+
+// HashFunc hashes a key into a shard index. Implementations should spread
+// keys uniformly; seed is random per Cache (see NewCache) so the distribution
+// can't be predicted by a caller trying to force hash collisions.
+type HashFunc[K comparable] func(seed maphash.Seed, key K) uint64
+
+// defaultHash hashes a key by feeding its string or byte representation
+// through maphash, which is itself randomly seeded per Cache. Arbitrary
+// comparable keys fall back to their fmt representation.
+func defaultHash[K comparable](seed maphash.Seed, key K) uint64 {
+	var h maphash.Hash
+	h.SetSeed(seed)
+	switch k := any(key).(type) {
+	case string:
+		h.WriteString(k)
+	case []byte:
+		h.Write(k)
+	default:
+		fmt.Fprintf(&h, "%v", k)
+	}
+	return h.Sum64()
+}
+
+// Cache is a generic, sharded, thread-safe LRU cache keyed by any
+// comparable type K. Unlike the original string-keyed, interface{}-valued
+// LRUCache, values of V are stored directly, avoiding the boxing
+// allocation that interface{} values incur.
+type Cache[K comparable, V any] struct {
+	shards    []*shard[K, V]
+	shardMask uint32
+	seed      maphash.Seed
+	hash      HashFunc[K]
+}
+
+// nextPowerOf2 rounds n up to the nearest power of two, so shardMask (built
+// from numShards-1) is a clean bitmask for getShard's hash lookup.
+func nextPowerOf2(n uint32) uint32 {
+	if n == 0 {
+		return 1
+	}
+	p := uint32(1)
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+// NewCache creates a cache with the given total capacity, split evenly
+// across shards and hashed with the default HashFunc.
+func NewCache[K comparable, V any](capacity int) *Cache[K, V] {
+	return NewCacheWithHash[K, V](capacity, defaultHash[K])
+}
+
+// NewCacheWithHash creates a cache like NewCache but with a custom
+// HashFunc, for keys whose default fmt-based hashing is too slow or too
+// collision-prone.
+func NewCacheWithHash[K comparable, V any](capacity int, hash HashFunc[K]) *Cache[K, V] {
+	numShards := nextPowerOf2(uint32(runtime.NumCPU() * 4))
+	if numShards < 16 {
+		numShards = 16
+	}
+	if numShards < 1024 {
+		numShards = 1024
+	}
+	shardCapacity := capacity / int(numShards)
+	if shardCapacity < 1 {
+		shardCapacity = 1
+	}
+
+	c := &Cache[K, V]{
+		shards:    make([]*shard[K, V], numShards),
+		shardMask: numShards - 1,
+		seed:      maphash.MakeSeed(),
+		hash:      hash,
+	}
+	for i := range c.shards {
+		c.shards[i] = newShard[K, V](shardCapacity)
+	}
+	return c
+}
+
+func (c *Cache[K, V]) getShard(key K) *shard[K, V] {
+	return c.shards[uint32(c.hash(c.seed, key))&c.shardMask]
+}
+
+// Add inserts or updates key, evicting the least recently used entry if
+// the shard is full. It reports whether an existing entry was evicted.
+func (c *Cache[K, V]) Add(key K, value V) (evicted bool) {
+	s := c.getShard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n, exists := s.items[key]; exists {
+		n.value = value
+		s.moveToFront(n)
+		return false
+	}
+
+	n := &node[K, V]{key: key, value: value}
+	s.items[key] = n
+	s.addToFront(n)
+	s.size++
+
+	for s.size > s.capacity {
+		s.evictOldest()
+		evicted = true
+	}
+	return evicted
+}
+
+// Get looks up key, marking it most recently used on a hit.
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	s := c.getShard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n, exists := s.items[key]; exists {
+		s.moveToFront(n)
+		return n.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Peek looks up key without affecting its recency.
+func (c *Cache[K, V]) Peek(key K) (value V, ok bool) {
+	s := c.getShard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if n, exists := s.items[key]; exists {
+		return n.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Contains reports whether key is present without affecting its recency.
+func (c *Cache[K, V]) Contains(key K) bool {
+	s := c.getShard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, exists := s.items[key]
+	return exists
+}
+
+// Remove deletes key, reporting whether it was present.
+func (c *Cache[K, V]) Remove(key K) bool {
+	s := c.getShard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, exists := s.items[key]
+	if !exists {
+		return false
+	}
+	s.removeNode(n)
+	delete(s.items, key)
+	s.size--
+	return true
+}
+
+// Keys returns all keys currently in the cache, in no particular order.
+func (c *Cache[K, V]) Keys() []K {
+	keys := make([]K, 0, c.Len())
+	for _, s := range c.shards {
+		s.mu.RLock()
+		for k := range s.items {
+			keys = append(keys, k)
+		}
+		s.mu.RUnlock()
+	}
+	return keys
+}
+
+// GetOldest returns the least recently used entry of the first non-empty
+// shard, in shard order, without removing it. Because recency is tracked
+// per shard rather than globally, this is not necessarily the oldest entry
+// across the whole cache.
+func (c *Cache[K, V]) GetOldest() (key K, value V, ok bool) {
+	for _, s := range c.shards {
+		s.mu.RLock()
+		oldest := s.tail.prev
+		if oldest != s.head {
+			key, value = oldest.key, oldest.value
+			ok = true
+		}
+		s.mu.RUnlock()
+		if ok {
+			return key, value, true
+		}
+	}
+	return key, value, false
+}
+
+// Len returns the total number of entries across all shards.
+func (c *Cache[K, V]) Len() int {
+	total := 0
+	for _, s := range c.shards {
+		s.mu.RLock()
+		total += s.size
+		s.mu.RUnlock()
+	}
+	return total
+}
@@ -0,0 +1,133 @@
+package lru
+
+import (
+	"hash/maphash"
+	"testing"
+)
+
+func TestNextPowerOf2(t *testing.T) {
+	cases := map[uint32]uint32{
+		0:    1,
+		1:    1,
+		2:    2,
+		3:    4,
+		1200: 2048,
+		1024: 1024,
+	}
+	for in, want := range cases {
+		if got := nextPowerOf2(in); got != want {
+			t.Errorf("nextPowerOf2(%d) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func TestNewCacheWithHashShardMaskIsPowerOfTwoMinusOne(t *testing.T) {
+	c := NewCache[string, string](1000)
+	n := c.shardMask + 1
+	if n&(n-1) != 0 {
+		t.Fatalf("shardMask+1 (%d) is not a power of two, so getShard's bitmask is incomplete", n)
+	}
+}
+
+func TestAddGetRoundTrip(t *testing.T) {
+	c := NewCache[string, int](64)
+	c.Add("a", 1)
+
+	v, ok := c.Get("a")
+	if !ok || v != 1 {
+		t.Fatalf("expected (1, true), got (%d, %v)", v, ok)
+	}
+	if _, ok := c.Get("missing"); ok {
+		t.Fatal("expected a miss for a key that was never added")
+	}
+}
+
+func TestAddUpdatesExistingKeyWithoutGrowingSize(t *testing.T) {
+	c := NewCache[string, int](64)
+	c.Add("a", 1)
+	c.Add("a", 2)
+
+	v, ok := c.Get("a")
+	if !ok || v != 2 {
+		t.Fatalf("expected updated value 2, got (%d, %v)", v, ok)
+	}
+	if got := c.Len(); got != 1 {
+		t.Fatalf("expected Len 1 after updating an existing key, got %d", got)
+	}
+}
+
+func TestAddEvictsOldestWhenShardIsFull(t *testing.T) {
+	s := newShard[string, int](2)
+	c := &Cache[string, int]{shards: []*shard[string, int]{s}, shardMask: 0, seed: maphash.MakeSeed(), hash: defaultHash[string]}
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+	if evicted := c.Add("c", 3); !evicted {
+		t.Fatal("expected Add to report an eviction once the shard is full")
+	}
+
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected the least recently used entry to have been evicted")
+	}
+	if _, ok := c.Get("b"); !ok {
+		t.Fatal("expected b to survive")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Fatal("expected c to survive")
+	}
+}
+
+func TestPeekDoesNotAffectRecency(t *testing.T) {
+	s := newShard[string, int](2)
+	c := &Cache[string, int]{shards: []*shard[string, int]{s}, shardMask: 0, seed: maphash.MakeSeed(), hash: defaultHash[string]}
+
+	c.Add("a", 1)
+	c.Add("b", 2)
+	if _, ok := c.Peek("a"); !ok {
+		t.Fatal("expected a to be present")
+	}
+	c.Add("c", 3) // a is still the least recently used; Peek must not have promoted it
+
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected Peek not to protect a key from eviction")
+	}
+}
+
+func TestContainsAndRemove(t *testing.T) {
+	c := NewCache[string, int](64)
+	c.Add("a", 1)
+
+	if !c.Contains("a") {
+		t.Fatal("expected Contains to report true for a present key")
+	}
+	if !c.Remove("a") {
+		t.Fatal("expected Remove to report true for a present key")
+	}
+	if c.Contains("a") {
+		t.Fatal("expected key to be gone after Remove")
+	}
+	if c.Remove("a") {
+		t.Fatal("expected a second Remove of the same key to report false")
+	}
+}
+
+func TestLenAndKeys(t *testing.T) {
+	c := NewCache[string, int](64)
+	want := map[string]int{"a": 1, "b": 2, "c": 3}
+	for k, v := range want {
+		c.Add(k, v)
+	}
+
+	if got := c.Len(); got != len(want) {
+		t.Fatalf("expected Len %d, got %d", len(want), got)
+	}
+	got := map[string]bool{}
+	for _, k := range c.Keys() {
+		got[k] = true
+	}
+	for k := range want {
+		if !got[k] {
+			t.Fatalf("expected Keys to include %q", k)
+		}
+	}
+}
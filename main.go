@@ -1,68 +1,208 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"hash/fnv"
+	"reflect"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/Stosan/Production-grade-LRU-cache-in-Go/lru"
 )
 
+// errLoaderPanicked is the error GetOrLoad/GetOrLoadCtx followers see when
+// the call they were waiting on panicked, instead of the zero-value (nil,
+// nil) a closed, never-populated done channel would otherwise produce.
+var errLoaderPanicked = errors.New("lru: loader panicked")
+
 type Node struct {
-	key   string
-	value interface{}
-	prev  *Node
-	next  *Node
+	key       string
+	value     interface{}
+	prev      *Node
+	next      *Node
+	visited   atomic.Bool // SIEVE only: set on Get, cleared as the hand sweeps past
+	expiresAt time.Time   // zero if the entry has no TTL
+	cost      int64       // byte-size-bounded mode only: this entry's charge against maxBytes
 }
 
+// Sizer estimates the byte cost of a key/value pair for a byte-size-bounded
+// cache (see NewLRUCacheBytes).
+type Sizer func(key string, v interface{}) int64
+
 type Shard struct {
-	mu       sync.RWMutex
-	capacity int
-	size     int
-	cache    map[string]*Node
-	head     *Node
-	tail     *Node
+	mu           sync.RWMutex
+	capacity     int
+	size         int
+	cache        map[string]*Node
+	head         *Node
+	tail         *Node
+	hand         *Node // SIEVE only: current sweep position, nil for other policies
+	policy       Policy
+	sweepCursor  *Node            // janitor's position in the list, nil means "start over"
+	ttlActive    int              // entries currently carrying a non-zero expiresAt
+	expiredCount int64            // entries reaped by Get or the janitor for being expired
+	maxBytes     int64            // byte-size-bounded mode only: 0 means item-count mode
+	bytesUsed    int64            // byte-size-bounded mode only: sum of live entries' cost
+	sizer        Sizer            // byte-size-bounded mode only: nil means item-count mode
+	inflight     map[string]*call // keys currently being loaded by GetOrLoad, nil until first use
+	admit        *tinyLFUFilter   // TinyLFU admission filter; nil means always admit (plain LRU)
+}
+
+// call tracks a single in-flight GetOrLoad for one key, so concurrent
+// misses on that key share one loader invocation instead of each running
+// their own.
+type call struct {
+	done chan struct{}
+	val  interface{}
+	err  error
 }
 
+const (
+	janitorInterval  = time.Second
+	janitorBatchSize = 64 // entries swept per shard per tick, to bound pause time
+)
+
 type LRUCache struct {
-	shards    []*Shard
-	shardMask uint32
+	shards     []*Shard
+	shardMask  uint32
+	defaultTTL time.Duration
+	stopCh     chan struct{}
+	closeOnce  sync.Once
+}
+
+// Policy controls how a Shard reacts to a cache hit and which node it
+// sacrifices when a shard is full. LRUCache delegates both decisions to a
+// Policy so new replacement strategies can be added without changing
+// Shard's bookkeeping.
+type Policy interface {
+	// touch runs on every cache hit, before the value is returned to the caller.
+	touch(s *Shard, n *Node)
+	// victim picks the node a full shard should evict next. The node is
+	// still linked into the list and present in the map; the caller
+	// unlinks it and deletes it from the map.
+	victim(s *Shard) *Node
+	// concurrentReads reports whether touch is safe to run under the
+	// shard's RLock instead of its Lock.
+	concurrentReads() bool
+}
+
+// LRU is the classic least-recently-used eviction policy: a hit moves its
+// node to the front of the list, and the victim is always the tail.
+func LRU() Policy { return lruPolicy{} }
+
+type lruPolicy struct{}
+
+func (lruPolicy) touch(s *Shard, n *Node) {
+	s.moveToFront(n)
+}
+
+func (lruPolicy) victim(s *Shard) *Node {
+	return s.tail.prev
 }
 
+func (lruPolicy) concurrentReads() bool { return false }
+
+// SIEVE implements the SIEVE eviction algorithm (Zhang et al., NSDI '24).
+// Reads just flip a visited bit instead of splicing the list, so a Get that
+// hits a live entry only needs the shard's read lock (reaping an expired
+// entry still takes the write lock). Eviction walks a "hand" backward from the
+// tail: a visited node is spared once (its bit is cleared) and the hand
+// steps to its predecessor; an unvisited node is evicted in place. New
+// entries are always inserted at the head, same as LRU.
+func SIEVE() Policy { return sievePolicy{} }
+
+type sievePolicy struct{}
+
+func (sievePolicy) touch(s *Shard, n *Node) {
+	// Concurrent Gets only hold the shard's RLock here, so this has to be an
+	// atomic store rather than a plain bool write to stay race-free.
+	n.visited.Store(true)
+}
+
+func (sievePolicy) victim(s *Shard) *Node {
+	hand := s.hand
+	if hand == nil {
+		hand = s.tail.prev
+	}
+	for {
+		if hand == s.head {
+			hand = s.tail.prev
+		}
+		if !hand.visited.Load() {
+			break
+		}
+		hand.visited.Store(false)
+		hand = hand.prev
+	}
+	s.hand = hand.prev
+	return hand
+}
+
+func (sievePolicy) concurrentReads() bool { return true }
+
+// nextPowerOf2 rounds n up to the nearest power of two, so callers that
+// index a table with a bitmask (h & (width-1)) actually get full coverage
+// of the table instead of silently aliasing into its low bits.
 func nextPowerOf2(n uint32) uint32 {
 	if n == 0 {
 		return 1
 	}
-	return n
+	p := uint32(1)
+	for p < n {
+		p <<= 1
+	}
+	return p
 }
 
-func newShard(capacity int) *Shard {
+func newShard(capacity int, policy Policy) *Shard {
 	shard := &Shard{
 		capacity: capacity,
 		size:     0,
 		cache:    make(map[string]*Node),
 		head:     &Node{},
 		tail:     &Node{},
+		policy:   policy,
 	}
-		// Initialize doubly-linked list with sentinel nodes
+	// Initialize doubly-linked list with sentinel nodes
 	shard.head.next = shard.tail
 	shard.tail.prev = shard.head
 
 	return shard
 }
 
-func (c LRUCache) hash(key string) uint32 {
+// newByteShard is newShard's counterpart for the byte-size-bounded mode:
+// the shard is capped by maxBytes instead of an item count, and sizer
+// computes the cost charged against it for each entry.
+func newByteShard(maxBytes int64, sizer Sizer, policy Policy) *Shard {
+	shard := newShard(0, policy)
+	shard.maxBytes = maxBytes
+	shard.sizer = sizer
+	return shard
+}
+
+func (c *LRUCache) hash(key string) uint32 {
 	h := fnv.New32a()
 	h.Write([]byte(key))
 	return h.Sum32()
 }
 
 // getShard returns the appropriate shard for a key
-func (c LRUCache) getShard(key string) *Shard {
+func (c *LRUCache) getShard(key string) *Shard {
 	return c.shards[c.hash(key)&c.shardMask]
 }
 
 func NewLRUCache(capacity int) *LRUCache {
+	return NewCacheWithPolicy(capacity, LRU())
+}
+
+// numShardsFor picks the shard count used by every constructor: four times
+// the CPU count rounded up to a power of two, but never fewer than 1024
+// shards, so small caches still get meaningful concurrency.
+func numShardsFor() uint32 {
 	numShards := nextPowerOf2(uint32(runtime.NumCPU() * 4))
 	if numShards < 16 {
 		numShards = 16
@@ -70,6 +210,14 @@ func NewLRUCache(capacity int) *LRUCache {
 	if numShards < 1024 {
 		numShards = 1024
 	}
+	return numShards
+}
+
+// NewCacheWithPolicy creates a sharded cache that evicts according to
+// policy instead of the default LRU. See Policy for the available
+// strategies.
+func NewCacheWithPolicy(capacity int, policy Policy) *LRUCache {
+	numShards := numShardsFor()
 	shardCapacity := capacity / int(numShards)
 	if shardCapacity < 1 {
 		shardCapacity = 1
@@ -78,13 +226,94 @@ func NewLRUCache(capacity int) *LRUCache {
 	cache := &LRUCache{
 		shards:    make([]*Shard, numShards),
 		shardMask: numShards - 1,
+		stopCh:    make(chan struct{}),
+	}
+	for i := uint32(0); i < numShards; i++ {
+		cache.shards[i] = newShard(shardCapacity, policy)
+	}
+	go cache.runJanitor()
+	return cache
+}
+
+// NewLRUCacheWithTTL creates a cache like NewLRUCache but where every Put
+// (not just PutWithTTL) expires after defaultTTL.
+func NewLRUCacheWithTTL(capacity int, defaultTTL time.Duration) *LRUCache {
+	cache := NewCacheWithPolicy(capacity, LRU())
+	cache.defaultTTL = defaultTTL
+	return cache
+}
+
+// NewLRUCacheBytes creates a cache bounded by total estimated byte size
+// instead of item count. Each shard gets an equal share of maxBytes and
+// evicts LRU entries until a newly inserted item fits; an item costing more
+// than the shard's whole share is evicted itself. sizer computes the
+// cost charged against that budget for a key/value pair; pass nil to use a
+// reflection-based default that handles strings, []byte, and primitive
+// types.
+func NewLRUCacheBytes(maxBytes int64, sizer Sizer) *LRUCache {
+	if sizer == nil {
+		sizer = defaultByteSizer
+	}
+	numShards := numShardsFor()
+	shardMaxBytes := maxBytes / int64(numShards)
+	if shardMaxBytes < 1 {
+		shardMaxBytes = 1
+	}
+
+	cache := &LRUCache{
+		shards:    make([]*Shard, numShards),
+		shardMask: numShards - 1,
+		stopCh:    make(chan struct{}),
 	}
 	for i := uint32(0); i < numShards; i++ {
-		cache.shards[i] = newShard(shardCapacity)
+		cache.shards[i] = newByteShard(shardMaxBytes, sizer, LRU())
 	}
+	go cache.runJanitor()
 	return cache
 }
 
+// defaultByteSizer estimates a key/value pair's footprint from the key's
+// length plus the value's size: len() for strings and []byte, and the
+// static in-memory size of the value's type for everything else (a
+// reflect.Value.Len()-free, allocation-free approximation good enough for
+// admission/eviction decisions, though it won't see through pointers).
+func defaultByteSizer(key string, v interface{}) int64 {
+	size := int64(len(key))
+	switch val := v.(type) {
+	case string:
+		size += int64(len(val))
+	case []byte:
+		size += int64(len(val))
+	default:
+		size += int64(reflect.TypeOf(v).Size())
+	}
+	return size
+}
+
+// runJanitor periodically sweeps each shard for expired entries until the
+// cache is closed. It runs as its own goroutine per cache.
+func (c *LRUCache) runJanitor() {
+	ticker := time.NewTicker(janitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+			for _, shard := range c.shards {
+				shard.sweepExpired(janitorBatchSize)
+			}
+		}
+	}
+}
+
+// Close stops the background janitor goroutine. It is safe to call more
+// than once.
+func (c *LRUCache) Close() {
+	c.closeOnce.Do(func() { close(c.stopCh) })
+}
+
 func (s *Shard) moveToFront(node *Node) {
 	s.removeNode(node)
 	s.addToFront(node)
@@ -101,36 +330,231 @@ func (s *Shard) removeNode(node *Node) {
 }
 func (c *LRUCache) Get(key string) (value interface{}, ok bool) {
 	shard := c.getShard(key)
+
+	if shard.policy.concurrentReads() {
+		shard.mu.RLock() // policy's touch never mutates the list, so readers can overlap
+		node, exists := shard.cache[key]
+		if exists && !node.expired() {
+			shard.policy.touch(shard, node)
+			value = node.value
+			shard.mu.RUnlock()
+			return value, true
+		}
+		shard.mu.RUnlock()
+		if !exists {
+			return nil, false
+		}
+		// node looked expired under the read lock; take the write lock to reap it.
+		shard.mu.Lock()
+		defer shard.mu.Unlock()
+		if node, exists := shard.cache[key]; exists && node.expired() {
+			shard.deleteNode(node)
+			shard.expiredCount++
+		}
+		return nil, false
+	}
+
 	shard.mu.Lock() // Minimize lock contention
 	defer shard.mu.Unlock()
 
 	if node, exists := shard.cache[key]; exists {
-		shard.moveToFront(node)
+		if node.expired() {
+			shard.deleteNode(node)
+			shard.expiredCount++
+			return nil, false
+		}
+		shard.policy.touch(shard, node)
+		if shard.admit != nil {
+			shard.admit.record(key)
+		}
 		return node.value, true
 	}
 	return nil, false
 }
+
+// expired reports whether the node's TTL (if any) has passed.
+func (n *Node) expired() bool {
+	return !n.expiresAt.IsZero() && !time.Now().Before(n.expiresAt)
+}
+
+// GetOrLoad returns the cached value for key, calling loader to populate it
+// on a miss. Concurrent misses for the same key share a single loader call:
+// the first caller runs it and the rest block on its result, which prevents
+// a thundering herd of identical loads hitting the backing store at once.
+func (c *LRUCache) GetOrLoad(key string, loader func() (interface{}, error)) (interface{}, error) {
+	return c.GetOrLoadCtx(context.Background(), key, loader)
+}
+
+// GetOrLoadCtx is GetOrLoad, but a caller that only waits on someone else's
+// in-flight load (rather than running the loader itself) gives up and
+// returns ctx.Err() if ctx is done first.
+func (c *LRUCache) GetOrLoadCtx(ctx context.Context, key string, loader func() (interface{}, error)) (interface{}, error) {
+	if value, ok := c.Get(key); ok {
+		return value, nil
+	}
+
+	shard := c.getShard(key)
+	shard.mu.Lock()
+	if node, exists := shard.cache[key]; exists && !node.expired() {
+		shard.policy.touch(shard, node)
+		value := node.value
+		shard.mu.Unlock()
+		return value, nil
+	}
+	if inflight, exists := shard.inflight[key]; exists {
+		shard.mu.Unlock()
+		select {
+		case <-inflight.done:
+			return inflight.val, inflight.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	inflight := &call{done: make(chan struct{})}
+	if shard.inflight == nil {
+		shard.inflight = make(map[string]*call)
+	}
+	shard.inflight[key] = inflight
+	shard.mu.Unlock()
+
+	// If loader panics, followers (including plain GetOrLoad callers, who
+	// have no ctx to time out on) must not block forever, so the inflight
+	// entry is always cleared and done always closed before we re-panic.
+	// A follower reading inflight.err off that close must see a real error
+	// rather than the zero value, or a panicked load looks like a hit.
+	func() {
+		panicked := true
+		defer func() {
+			if panicked {
+				inflight.val = nil
+				inflight.err = errLoaderPanicked
+			}
+			shard.mu.Lock()
+			delete(shard.inflight, key)
+			shard.mu.Unlock()
+			close(inflight.done)
+		}()
+		inflight.val, inflight.err = loader()
+		panicked = false
+	}()
+	if inflight.err == nil {
+		c.Put(key, inflight.val)
+	}
+
+	return inflight.val, inflight.err
+}
+
+// Put inserts or updates key, expiring it after the cache's defaultTTL (no
+// expiration if the cache was created without one).
 func (c *LRUCache) Put(key string, value interface{}) {
+	c.putWithTTL(key, value, c.defaultTTL)
+}
+
+// PutWithTTL inserts or updates key, overriding the cache's default TTL for
+// this entry. A zero ttl means the entry never expires.
+func (c *LRUCache) PutWithTTL(key string, value interface{}, ttl time.Duration) {
+	c.putWithTTL(key, value, ttl)
+}
+
+func (c *LRUCache) putWithTTL(key string, value interface{}, ttl time.Duration) {
 	shard := c.getShard(key)
 	shard.mu.Lock() // Minimize lock contention
 	defer shard.mu.Unlock()
 
+	var cost int64
+	if shard.sizer != nil {
+		cost = shard.sizer(key, value)
+	}
+
 	if node, exists := shard.cache[key]; exists {
+		shard.bytesUsed += cost - node.cost
+		node.cost = cost
+		if !node.expiresAt.IsZero() {
+			shard.ttlActive--
+		}
 		node.value = value
-		shard.moveToFront(node)
+		node.expiresAt = time.Time{}
+		if ttl > 0 {
+			node.expiresAt = time.Now().Add(ttl)
+			shard.ttlActive++
+		}
+		shard.policy.touch(shard, node)
+		if shard.admit != nil {
+			shard.admit.record(key)
+		}
+		for shard.full() {
+			shard.evictLRU()
+		}
 		return
 	}
+
+	if shard.admit != nil {
+		shard.admit.record(key)
+		if victim := shard.policy.victim(shard); victim != shard.head && shard.wouldOverflow(cost) {
+			if shard.admit.frequency(key) < shard.admit.frequency(victim.key) {
+				return // new key isn't popular enough to evict the current victim
+			}
+		}
+	}
+
 	newNode := &Node{
 		key:   key,
 		value: value,
+		cost:  cost,
+	}
+	if ttl > 0 {
+		newNode.expiresAt = time.Now().Add(ttl)
+		shard.ttlActive++
 	}
 	shard.cache[key] = newNode
 	shard.addToFront(newNode)
 	shard.size++
+	shard.bytesUsed += cost
 
-	if shard.size > shard.capacity {
-		return // need a better logic
+	for shard.full() {
+		shard.evictLRU()
+	}
+}
+
+// full reports whether the shard is over its bound: bytesUsed vs. maxBytes
+// in byte-size-bounded mode, size vs. capacity otherwise.
+func (s *Shard) full() bool {
+	if s.sizer != nil {
+		return s.bytesUsed > s.maxBytes
+	}
+	return s.size > s.capacity
+}
+
+// wouldOverflow reports whether inserting one more entry of the given cost
+// would push the shard over its bound, without actually inserting it.
+func (s *Shard) wouldOverflow(cost int64) bool {
+	if s.sizer != nil {
+		return s.bytesUsed+cost > s.maxBytes
+	}
+	return s.size >= s.capacity
+}
+
+// sweepExpired examines up to budget entries, reaping the expired ones among
+// them, and resumes from where the previous sweep left off so a shard with many entries is swept gradually
+// across several janitor ticks rather than all at once.
+func (s *Shard) sweepExpired(budget int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cursor := s.sweepCursor
+	if cursor == nil || cursor == s.tail {
+		cursor = s.head.next
+	}
+	for i := 0; i < budget && cursor != s.tail; i++ {
+		next := cursor.next
+		if cursor.expired() {
+			s.deleteNode(cursor)
+			s.expiredCount++
+		}
+		cursor = next
 	}
+	s.sweepCursor = cursor
 }
 
 // Clear removes all items from the cache
@@ -140,18 +564,40 @@ func (c *LRUCache) Clear() {
 		shard.cache = make(map[string]*Node)
 		shard.head.next = shard.tail
 		shard.tail.prev = shard.head
+		shard.hand = nil
+		shard.sweepCursor = nil
 		shard.size = 0
+		shard.ttlActive = 0
+		shard.bytesUsed = 0
 		shard.mu.Unlock()
 	}
 }
 
-// evictLRU removes the least recently used item
+// evictLRU removes the item the shard's policy picks as the next victim.
 func (s *Shard) evictLRU() {
-	lru := s.tail.prev
-	if lru != s.head {
-		s.removeNode(lru)
-		delete(s.cache, lru.key)
-		s.size--
+	victim := s.policy.victim(s)
+	if victim == s.head {
+		return
+	}
+	s.deleteNode(victim)
+}
+
+// deleteNode unlinks n from the list, removes it from the map, and keeps
+// the shard's bookkeeping (size, bytesUsed, hand, TTL count) consistent.
+// Callers must hold s.mu for writing.
+func (s *Shard) deleteNode(n *Node) {
+	if s.hand == n {
+		s.hand = n.prev
+	}
+	if s.sweepCursor == n {
+		s.sweepCursor = n.next
+	}
+	s.removeNode(n)
+	delete(s.cache, n.key)
+	s.size--
+	s.bytesUsed -= n.cost
+	if !n.expiresAt.IsZero() {
+		s.ttlActive--
 	}
 }
 
@@ -166,32 +612,55 @@ func (c *LRUCache) Size() int {
 	return total
 }
 
+// BytesUsed returns the total estimated byte size of all entries. It is
+// always 0 for caches created with NewLRUCache / NewCacheWithPolicy.
+func (c *LRUCache) BytesUsed() int64 {
+	var total int64
+	for _, shard := range c.shards {
+		shard.mu.RLock()
+		total += shard.bytesUsed
+		shard.mu.RUnlock()
+	}
+	return total
+}
+
 func (c *LRUCache) Stats() map[string]interface{} {
 	totalSize := 0
 	shardSizes := make([]int, len(c.shards))
+	shardBytes := make([]int64, len(c.shards))
+	var expiredCount int64
+	var activeTTLEntries int
+	var totalBytes int64
 
 	for i, shard := range c.shards {
 		shard.mu.RLock()
 		shardSizes[i] = shard.size
 		totalSize += shard.size
+		shardBytes[i] = shard.bytesUsed
+		totalBytes += shard.bytesUsed
+		expiredCount += shard.expiredCount
+		activeTTLEntries += shard.ttlActive
 		shard.mu.RUnlock()
 	}
 
 	return map[string]interface{}{
-		"total_size":  totalSize,
-		"num_shards":  len(c.shards),
-		"shard_sizes": shardSizes,
+		"total_size":         totalSize,
+		"num_shards":         len(c.shards),
+		"shard_sizes":        shardSizes,
+		"expired_count":      expiredCount,
+		"active_ttl_entries": activeTTLEntries,
+		"bytes_used":         totalBytes,
+		"shard_bytes":        shardBytes,
 	}
 }
 
 func main() {
-	cache := NewLRUCache(1000000)
+	cache := lru.NewCache[string, string](1000000)
 	fmt.Println("LRU Cache has been created with 1M capacity")
-	fmt.Printf("Number of shards: %d", len(cache.shards))
-	cache.Put("user:1", "Tony")
-	cache.Put("user:2", "Ayo")
-	
-	cache.Put("session:abc098", map[string]string{"token": "xyz123"})
+	cache.Add("user:1", "Tony")
+	cache.Add("user:2", "Ayo")
+
+	cache.Add("session:abc098", "token:xyz123")
 	if value, ok := cache.Get("user:1"); ok {
 		fmt.Printf("Found user:1= %v\n", value)
 	}
@@ -221,7 +690,7 @@ func main() {
 				if j%3 == 0 {
 					cache.Get(key) // May not exist yet
 				} else {
-					cache.Put(key, value)
+					cache.Add(key, value)
 				}
 			}
 		}(i)
@@ -235,6 +704,5 @@ func main() {
 	fmt.Printf("Throughput: %.0f ops/second\n", float64(totalOps)/duration.Seconds())
 
 	// Print final stats
-	stats := cache.Stats()
-	fmt.Printf("\nFinal cache stats: %+v\n", stats)
+	fmt.Printf("\nFinal cache size: %d\n", cache.Len())
 }
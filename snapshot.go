@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"time"
+)
+
+// snapshotMagic identifies the framed format written by Snapshot, so
+// Restore can fail fast on unrecognized input instead of misparsing it.
+var snapshotMagic = [4]byte{'L', 'R', 'U', '1'}
+
+// Snapshot writes every shard's entries to w in a compact framed format:
+// a magic header, a shard count, then one section per shard giving its
+// index and entry count followed by that many (key, optional TTL, gob
+// value) records in most-recently-used-first order. Values must have been
+// registered with gob.Register if they're not one of gob's builtin types,
+// the same requirement gob always places on encoding interface{} fields.
+func (c *LRUCache) Snapshot(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(snapshotMagic[:]); err != nil {
+		return fmt.Errorf("lru: writing snapshot header: %w", err)
+	}
+	if err := writeUvarint(bw, uint64(len(c.shards))); err != nil {
+		return fmt.Errorf("lru: writing shard count: %w", err)
+	}
+	for i, shard := range c.shards {
+		if err := shard.snapshot(bw, i); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// Restore reads a snapshot written by Snapshot and inserts its entries into
+// c, rehashing each key against c's own shard count so a snapshot taken
+// from a cache with a different number of shards restores cleanly. Entries
+// whose TTL has already elapsed since the snapshot was taken are dropped
+// rather than resurrected.
+func (c *LRUCache) Restore(r io.Reader) error {
+	br := bufio.NewReader(r)
+
+	var magic [4]byte
+	if _, err := io.ReadFull(br, magic[:]); err != nil {
+		return fmt.Errorf("lru: reading snapshot header: %w", err)
+	}
+	if magic != snapshotMagic {
+		return fmt.Errorf("lru: input is not a recognized cache snapshot")
+	}
+
+	numShards, err := binary.ReadUvarint(br)
+	if err != nil {
+		return fmt.Errorf("lru: reading shard count: %w", err)
+	}
+
+	now := time.Now()
+	for i := uint64(0); i < numShards; i++ {
+		if _, err := binary.ReadUvarint(br); err != nil { // source shard index; unused, keys are rehashed
+			return fmt.Errorf("lru: reading index of shard %d: %w", i, err)
+		}
+		count, err := binary.ReadUvarint(br)
+		if err != nil {
+			return fmt.Errorf("lru: reading entry count of shard %d: %w", i, err)
+		}
+		entries := make([]restoredEntry, count)
+		for j := uint64(0); j < count; j++ {
+			key, value, expiresAt, err := readNode(br)
+			if err != nil {
+				return fmt.Errorf("lru: reading entry %d of shard %d: %w", j, i, err)
+			}
+			entries[j] = restoredEntry{key: key, value: value, expiresAt: expiresAt}
+		}
+		// snapshot wrote entries most-recently-used first, but c.Put always
+		// inserts at the front, so entries must be replayed in reverse
+		// (least-recently-used first) or recency comes out backwards —
+		// evicting the wrong entries if the restored cache is smaller.
+		for j := len(entries) - 1; j >= 0; j-- {
+			e := entries[j]
+			if e.expiresAt.IsZero() {
+				c.Put(e.key, e.value)
+				continue
+			}
+			if ttl := e.expiresAt.Sub(now); ttl > 0 {
+				c.PutWithTTL(e.key, e.value, ttl)
+			}
+		}
+	}
+	return nil
+}
+
+// restoredEntry holds one entry read from a snapshot until Restore knows the
+// full shard section and can replay entries in the right order.
+type restoredEntry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+// snapshot writes this shard's section: its index, entry count, and then
+// each entry from most to least recently used. Callers hold no lock; it
+// takes its own read lock so a snapshot can run alongside live traffic.
+func (s *Shard) snapshot(w io.Writer, idx int) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := writeUvarint(w, uint64(idx)); err != nil {
+		return fmt.Errorf("lru: writing index of shard %d: %w", idx, err)
+	}
+	if err := writeUvarint(w, uint64(s.size)); err != nil {
+		return fmt.Errorf("lru: writing entry count of shard %d: %w", idx, err)
+	}
+	for n := s.head.next; n != s.tail; n = n.next {
+		if err := writeNode(w, n); err != nil {
+			return fmt.Errorf("lru: writing entry of shard %d: %w", idx, err)
+		}
+	}
+	return nil
+}
+
+func writeNode(w io.Writer, n *Node) error {
+	if err := writeUvarint(w, uint64(len(n.key))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, n.key); err != nil {
+		return err
+	}
+
+	if n.expiresAt.IsZero() {
+		if _, err := w.Write([]byte{0}); err != nil {
+			return err
+		}
+	} else {
+		if _, err := w.Write([]byte{1}); err != nil {
+			return err
+		}
+		if err := writeUvarint(w, uint64(n.expiresAt.UnixNano())); err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&n.value); err != nil {
+		return fmt.Errorf("encoding value for key %q: %w", n.key, err)
+	}
+	if err := writeUvarint(w, uint64(buf.Len())); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func readNode(r *bufio.Reader) (key string, value interface{}, expiresAt time.Time, err error) {
+	keyLen, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", nil, time.Time{}, err
+	}
+	keyBytes := make([]byte, keyLen)
+	if _, err := io.ReadFull(r, keyBytes); err != nil {
+		return "", nil, time.Time{}, err
+	}
+
+	hasTTL, err := r.ReadByte()
+	if err != nil {
+		return "", nil, time.Time{}, err
+	}
+	if hasTTL == 1 {
+		nanos, err := binary.ReadUvarint(r)
+		if err != nil {
+			return "", nil, time.Time{}, err
+		}
+		expiresAt = time.Unix(0, int64(nanos))
+	}
+
+	valLen, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", nil, time.Time{}, err
+	}
+	valBytes := make([]byte, valLen)
+	if _, err := io.ReadFull(r, valBytes); err != nil {
+		return "", nil, time.Time{}, err
+	}
+	if err := gob.NewDecoder(bytes.NewReader(valBytes)).Decode(&value); err != nil {
+		return "", nil, time.Time{}, fmt.Errorf("decoding value for key %q: %w", string(keyBytes), err)
+	}
+
+	return string(keyBytes), value, expiresAt, nil
+}
+
+func writeUvarint(w io.Writer, v uint64) error {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	_, err := w.Write(buf[:n])
+	return err
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRestoreRoundTrip(t *testing.T) {
+	src := NewLRUCache(1024 * 64)
+	defer src.Close()
+
+	const n = 200
+	for i := 0; i < n; i++ {
+		src.Put(fmt.Sprintf("key-%d", i), fmt.Sprintf("val-%d", i))
+	}
+	src.PutWithTTL("ttl-key", "ttl-val", time.Hour)
+
+	var buf bytes.Buffer
+	if err := src.Snapshot(&buf); err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+
+	// Restore into a cache with a different shard count than the source, to
+	// exercise rehashing keys rather than assuming shard layout matches.
+	dst := &LRUCache{shards: make([]*Shard, 4), shardMask: 3, stopCh: make(chan struct{})}
+	for i := range dst.shards {
+		dst.shards[i] = newShard(1024, LRU())
+	}
+	defer dst.Close()
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	for i := 0; i < n; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		want := fmt.Sprintf("val-%d", i)
+		got, ok := dst.Get(key)
+		if !ok || got != want {
+			t.Fatalf("key %s: expected %s, got %v (ok=%v)", key, want, got, ok)
+		}
+	}
+
+	if got, ok := dst.Get("ttl-key"); !ok || got != "ttl-val" {
+		t.Fatalf("expected ttl-key to survive restore, got %v (ok=%v)", got, ok)
+	}
+	stats := dst.Stats()
+	if stats["active_ttl_entries"].(int) < 1 {
+		t.Fatal("expected restored entry's TTL to still be tracked")
+	}
+}
+
+func TestSnapshotRestoreDropsExpiredEntry(t *testing.T) {
+	src := NewLRUCache(16)
+	defer src.Close()
+	src.PutWithTTL("soon-gone", "v", 10*time.Millisecond)
+
+	var buf bytes.Buffer
+	if err := src.Snapshot(&buf); err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond) // let the TTL elapse before Restore reads it
+
+	dst := NewLRUCache(16)
+	defer dst.Close()
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	if _, ok := dst.Get("soon-gone"); ok {
+		t.Fatal("expected an already-expired entry not to be restored")
+	}
+}
+
+func TestRestoreIntoSmallerCapacityKeepsMostRecentlyUsed(t *testing.T) {
+	src := &LRUCache{shards: []*Shard{newShard(4, LRU())}, shardMask: 0, stopCh: make(chan struct{})}
+	defer src.Close()
+
+	// Put order k1,k2,k3,k0 leaves k0 MRU and k1 LRU: front-to-back k0,k3,k2,k1.
+	for _, k := range []string{"k1", "k2", "k3", "k0"} {
+		src.Put(k, k)
+	}
+
+	var buf bytes.Buffer
+	if err := src.Snapshot(&buf); err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+
+	dst := &LRUCache{shards: []*Shard{newShard(2, LRU())}, shardMask: 0, stopCh: make(chan struct{})}
+	defer dst.Close()
+	if err := dst.Restore(&buf); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	for _, k := range []string{"k0", "k3"} {
+		if _, ok := dst.Get(k); !ok {
+			t.Fatalf("expected most-recently-used key %s to survive restore into a smaller cache", k)
+		}
+	}
+	for _, k := range []string{"k1", "k2"} {
+		if _, ok := dst.Get(k); ok {
+			t.Fatalf("expected least-recently-used key %s to be evicted, not retained", k)
+		}
+	}
+}
+
+func TestRestoreRejectsUnrecognizedInput(t *testing.T) {
+	dst := NewLRUCache(16)
+	defer dst.Close()
+
+	err := dst.Restore(bytes.NewReader([]byte("not a snapshot")))
+	if err == nil {
+		t.Fatal("expected Restore to reject input without the snapshot magic header")
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPutWithTTLExpires(t *testing.T) {
+	c := NewLRUCache(16)
+	defer c.Close()
+
+	c.PutWithTTL("k", "v", 10*time.Millisecond)
+	if v, ok := c.Get("k"); !ok || v != "v" {
+		t.Fatalf("expected immediate hit, got %v %v", v, ok)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := c.Get("k"); ok {
+		t.Fatal("expected key to have expired")
+	}
+}
+
+func TestPutWithoutTTLNeverExpires(t *testing.T) {
+	c := NewLRUCache(16)
+	defer c.Close()
+
+	c.Put("k", "v")
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := c.Get("k"); !ok {
+		t.Fatal("expected key without a TTL to remain cached")
+	}
+}
+
+func TestDefaultTTLAppliesToPut(t *testing.T) {
+	c := NewLRUCacheWithTTL(16, 10*time.Millisecond)
+	defer c.Close()
+
+	c.Put("k", "v")
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := c.Get("k"); ok {
+		t.Fatal("expected defaultTTL to expire a plain Put")
+	}
+}
+
+func TestJanitorSweepsExpiredEntries(t *testing.T) {
+	c := NewLRUCache(16)
+	defer c.Close()
+
+	shard := c.getShard("k")
+	c.PutWithTTL("k", "v", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	shard.sweepExpired(janitorBatchSize)
+
+	shard.mu.RLock()
+	_, stillPresent := shard.cache["k"]
+	expired := shard.expiredCount
+	shard.mu.RUnlock()
+
+	if stillPresent {
+		t.Fatal("expected janitor sweep to reap the expired entry")
+	}
+	if expired != 1 {
+		t.Fatalf("expected expiredCount 1, got %d", expired)
+	}
+}
+
+func TestStatsReportsActiveTTLEntries(t *testing.T) {
+	c := NewLRUCache(16)
+	defer c.Close()
+
+	c.PutWithTTL("k", "v", time.Minute)
+	c.Put("no-ttl", "v")
+
+	stats := c.Stats()
+	if got := stats["active_ttl_entries"].(int); got != 1 {
+		t.Fatalf("expected 1 active TTL entry, got %d", got)
+	}
+}